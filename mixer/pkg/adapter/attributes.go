@@ -0,0 +1,56 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"fmt"
+	"sort"
+)
+
+// KeyValue is a single dimensional data point, as produced from a
+// Value's Labels. It is backend-agnostic; adapters convert it into
+// whatever key/value representation their wire format requires (e.g.
+// Prometheus label pairs, OpenTelemetry attribute.KeyValue).
+type KeyValue struct {
+	Key   string
+	Value interface{}
+}
+
+// ToKeyValues converts a Value's Labels into a deterministically ordered
+// slice of KeyValue pairs. Sorting by key keeps output stable across
+// repeated calls with the same Labels, which matters for adapters that
+// derive a series identity (e.g. for delta temporality tracking) from the
+// serialized label set.
+func ToKeyValues(labels map[string]interface{}) []KeyValue {
+	kvs := make([]KeyValue, 0, len(labels))
+	for k, v := range labels {
+		kvs = append(kvs, KeyValue{Key: k, Value: v})
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return kvs
+}
+
+// SeriesKey returns a stable string identity for a metric value's series,
+// derived from the metric name and its sorted label values. Adapters that
+// need to track per-series state across Record() calls (e.g. computing
+// delta temporality from cumulative counters) can use it as a map key
+// instead of re-deriving one from scratch.
+func SeriesKey(name string, labels map[string]interface{}) string {
+	key := name
+	for _, kv := range ToKeyValues(labels) {
+		key += fmt.Sprintf(",%s=%v", kv.Key, kv.Value)
+	}
+	return key
+}
@@ -0,0 +1,108 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"fmt"
+	"math"
+)
+
+// BucketIndex returns the index into a DistributionValue.Counts slice (as
+// produced for the given BucketDefinition) that a raw observation falls
+// into. Bucket 0 is the underflow bucket and covers (-inf, bounds[0]);
+// the last bucket is the overflow bucket and covers [bounds[N-1], inf).
+// All other buckets are half-open on the upper bound: [bounds[i-1], bounds[i]).
+func BucketIndex(buckets BucketDefinition, value float64) (int, error) {
+	switch b := buckets.(type) {
+	case LinearBuckets:
+		return linearBucketIndex(b, value), nil
+	case ExponentialBuckets:
+		return exponentialBucketIndex(b, value), nil
+	case ExplicitBuckets:
+		return explicitBucketIndex(b, value), nil
+	default:
+		return 0, fmt.Errorf("unsupported bucket definition type %T", buckets)
+	}
+}
+
+func linearBucketIndex(b LinearBuckets, value float64) int {
+	// Bucket i (1 <= i <= Count) covers [offset + width*(i-1), offset + width*i).
+	idx := int(math.Floor((value-b.Offset)/b.Width)) + 1
+	return clampBucketIndex(idx, int(b.Count)+2)
+}
+
+func exponentialBucketIndex(b ExponentialBuckets, value float64) int {
+	if value < b.Scale {
+		return clampBucketIndex(0, int(b.Count)+2)
+	}
+	idx := 0
+	for bound := b.Scale; value >= bound; bound *= b.GrowthFactor {
+		idx++
+	}
+	return clampBucketIndex(idx, int(b.Count)+2)
+}
+
+func explicitBucketIndex(b ExplicitBuckets, value float64) int {
+	for i, bound := range b.Bounds {
+		if value < bound {
+			return i
+		}
+	}
+	return len(b.Bounds)
+}
+
+func clampBucketIndex(idx, numBuckets int) int {
+	if idx < 0 {
+		return 0
+	}
+	if idx > numBuckets-1 {
+		return numBuckets - 1
+	}
+	return idx
+}
+
+// Bounds returns the finite bucket boundaries implied by a BucketDefinition,
+// in increasing order. This is the slice adapters hand to histogram APIs
+// that want explicit boundaries (e.g. Prometheus, Stackdriver, or the
+// OpenTelemetry SDK's WithExplicitBucketBoundaries), so that boundary
+// computation for Linear/Exponential buckets is only implemented once.
+func Bounds(buckets BucketDefinition) ([]float64, error) {
+	switch b := buckets.(type) {
+	case LinearBuckets:
+		// N = Count+2 buckets; the N-1 = Count+1 finite boundaries are the
+		// upper bounds of buckets 0..Count: offset + width*i.
+		bounds := make([]float64, b.Count+1)
+		for i := range bounds {
+			bounds[i] = b.Offset + b.Width*float64(i)
+		}
+		return bounds, nil
+	case ExponentialBuckets:
+		// N = Count+2 buckets; the N-1 = Count+1 finite boundaries are the
+		// upper bounds of buckets 0..Count: scale * growth_factor^i.
+		bounds := make([]float64, b.Count+1)
+		bound := b.Scale
+		for i := range bounds {
+			bounds[i] = bound
+			bound *= b.GrowthFactor
+		}
+		return bounds, nil
+	case ExplicitBuckets:
+		bounds := make([]float64, len(b.Bounds))
+		copy(bounds, b.Bounds)
+		return bounds, nil
+	default:
+		return nil, fmt.Errorf("unsupported bucket definition type %T", buckets)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import "testing"
+
+func TestLinearBucketIndex(t *testing.T) {
+	b := LinearBuckets{Offset: 0, Width: 1, Count: 3}
+
+	cases := []struct {
+		value float64
+		want  int
+	}{
+		{-1.5, 0}, // underflow
+		{-0.5, 0}, // negative fractional offset must floor, not truncate
+		{0, 1},    // [0, 1)
+		{0.5, 1},  // [0, 1)
+		{1, 2},    // [1, 2)
+		{2.9, 3},  // [2, 3)
+		{3, 4},    // overflow
+		{100, 4},  // overflow
+	}
+	for _, c := range cases {
+		if got := linearBucketIndex(b, c.value); got != c.want {
+			t.Errorf("linearBucketIndex(%v) = %d, want %d", c.value, got, c.want)
+		}
+	}
+}
+
+func TestExponentialBucketIndex(t *testing.T) {
+	b := ExponentialBuckets{Scale: 2, GrowthFactor: 2, Count: 3}
+
+	cases := []struct {
+		value float64
+		want  int
+	}{
+		{1, 0}, // underflow: value < Scale
+		{2, 1}, // [Scale, Scale*GrowthFactor) boundary is inclusive on the low end
+		{3, 1},
+		{4, 2},
+		{8, 3},
+		{16, 4}, // overflow
+	}
+	for _, c := range cases {
+		if got := exponentialBucketIndex(b, c.value); got != c.want {
+			t.Errorf("exponentialBucketIndex(%v) = %d, want %d", c.value, got, c.want)
+		}
+	}
+}
+
+func TestExplicitBucketIndex(t *testing.T) {
+	b := ExplicitBuckets{Bounds: []float64{0, 1, 2}}
+
+	cases := []struct {
+		value float64
+		want  int
+	}{
+		{-1, 0},
+		{0, 1},
+		{0.5, 1},
+		{2, 3},
+		{100, 3},
+	}
+	for _, c := range cases {
+		if got := explicitBucketIndex(b, c.value); got != c.want {
+			t.Errorf("explicitBucketIndex(%v) = %d, want %d", c.value, got, c.want)
+		}
+	}
+}
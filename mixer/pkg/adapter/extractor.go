@@ -0,0 +1,335 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type (
+	// AttributeBag is the minimal view of a Report()'s attributes that the
+	// extractor expression language needs. It is satisfied by the mixer's
+	// attribute bag implementation.
+	AttributeBag interface {
+		// Get returns the named attribute's value, and false if it is not
+		// present.
+		Get(name string) (value interface{}, found bool)
+	}
+
+	// ValueExtractorFn evaluates a compiled ValueExtractor expression
+	// against a Report()'s attributes, producing the raw value to be
+	// reported for a metric.
+	ValueExtractorFn func(bag AttributeBag) (interface{}, error)
+
+	// LabelExtractorFn evaluates a compiled label expression against a
+	// Report()'s attributes, producing the value for a single label.
+	LabelExtractorFn func(bag AttributeBag) (interface{}, error)
+
+	// ExtractorCompiler compiles the ValueExtractor and LabelExtractors
+	// expressions on a MetricDefinition once, at config load time, so that
+	// Report() only has to evaluate already-compiled expressions rather
+	// than parsing them on every call. Implementations type-check each
+	// expression against the declared LabelType map and the metric's Kind.
+	ExtractorCompiler interface {
+		// CompileValueExtractor compiles expr, the MetricDefinition's
+		// ValueExtractor, checking that its result type is compatible
+		// with kind.
+		CompileValueExtractor(expr string, labelTypes map[string]LabelType, kind MetricKind) (ValueExtractorFn, error)
+
+		// CompileLabelExtractor compiles expr, a single entry from the
+		// MetricDefinition's LabelExtractors map, checking that its
+		// result type is compatible with labelType.
+		CompileLabelExtractor(expr string, labelTypes map[string]LabelType, labelType LabelType) (LabelExtractorFn, error)
+	}
+)
+
+// extractorCompiler is the default ExtractorCompiler, backed by compileExpr
+// and typeOfExpr.
+type extractorCompiler struct{}
+
+// NewExtractorCompiler returns the default ExtractorCompiler for the
+// expression language described on compileExpr. The mixer uses it to
+// compile a MetricDefinition's ValueExtractor and LabelExtractors
+// extractors once, at config load time.
+func NewExtractorCompiler() ExtractorCompiler {
+	return extractorCompiler{}
+}
+
+func (extractorCompiler) CompileValueExtractor(expr string, labelTypes map[string]LabelType, kind MetricKind) (ValueExtractorFn, error) {
+	typ, err := typeOfExpr(expr, labelTypes)
+	if err != nil {
+		return nil, fmt.Errorf("value extractor %q: %v", expr, err)
+	}
+	if typ != Int64Label && typ != DoubleLabel {
+		return nil, fmt.Errorf("value extractor %q: result type %d is not numeric, required for metric kind %d", expr, typ, kind)
+	}
+	fn, err := compileExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	return ValueExtractorFn(fn), nil
+}
+
+func (extractorCompiler) CompileLabelExtractor(expr string, labelTypes map[string]LabelType, labelType LabelType) (LabelExtractorFn, error) {
+	typ, err := typeOfExpr(expr, labelTypes)
+	if err != nil {
+		return nil, fmt.Errorf("label extractor %q: %v", expr, err)
+	}
+	if typ != labelType {
+		return nil, fmt.Errorf("label extractor %q: result type %d does not match declared label type %d", expr, typ, labelType)
+	}
+	fn, err := compileExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	return LabelExtractorFn(fn), nil
+}
+
+// typeOfExpr statically determines the LabelType an expression produces,
+// mirroring compileExpr's recognized forms, so ExtractorCompiler can
+// type-check a MetricDefinition's extractors without evaluating them.
+func typeOfExpr(expr string, labelTypes map[string]LabelType) (LabelType, error) {
+	expr = strings.TrimSpace(expr)
+
+	if lhs, rhs, ok := splitTopLevelEq(expr); ok {
+		lhsType, err := typeOfExpr(lhs, labelTypes)
+		if err != nil {
+			return 0, err
+		}
+		rhsType, err := typeOfExpr(rhs, labelTypes)
+		if err != nil {
+			return 0, err
+		}
+		if lhsType != rhsType || (lhsType != StringLabel && lhsType != BoolLabel) {
+			return 0, fmt.Errorf("== requires two string or two bool operands, got types %d and %d", lhsType, rhsType)
+		}
+		return BoolLabel, nil
+	}
+
+	if expr == "true" || expr == "false" {
+		return BoolLabel, nil
+	}
+
+	if m := regexpExtractCall.FindStringSubmatch(expr); m != nil {
+		if _, err := typeOfExpr(m[1], labelTypes); err != nil {
+			return 0, err
+		}
+		return StringLabel, nil
+	}
+
+	if m := extractCall.FindStringSubmatch(expr); m != nil {
+		innerType, err := typeOfExpr(m[1], labelTypes)
+		if err != nil {
+			return 0, err
+		}
+		if innerType != StringMapLabel {
+			return 0, fmt.Errorf("EXTRACT requires a string map operand, got type %d", innerType)
+		}
+		return StringLabel, nil
+	}
+
+	if m := toLowerCall.FindStringSubmatch(expr); m != nil {
+		innerType, err := typeOfExpr(m[1], labelTypes)
+		if err != nil {
+			return 0, err
+		}
+		if innerType != StringLabel {
+			return 0, fmt.Errorf("to_lower requires a string operand, got type %d", innerType)
+		}
+		return StringLabel, nil
+	}
+
+	if strings.HasPrefix(expr, `"`) && strings.HasSuffix(expr, `"`) && len(expr) >= 2 {
+		return StringLabel, nil
+	}
+
+	name, _, hasKey := splitFieldAccess(expr)
+	typ, ok := labelTypes[name]
+	if !ok {
+		return 0, fmt.Errorf("reference to undeclared attribute %q", name)
+	}
+	if hasKey {
+		if typ != StringMapLabel {
+			return 0, fmt.Errorf("%q is not declared as a string map attribute", name)
+		}
+		return StringLabel, nil
+	}
+	return typ, nil
+}
+
+var regexpExtractCall = regexp.MustCompile(`^REGEXP_EXTRACT\((.+),\s*"((?:[^"\\]|\\.)*)"\)$`)
+var extractCall = regexp.MustCompile(`^EXTRACT\((.+),\s*"((?:[^"\\]|\\.)*)"\)$`)
+var toLowerCall = regexp.MustCompile(`^to_lower\((.+)\)$`)
+
+// compileExpr is the default, dependency-free implementation of the
+// extractor expression language: field access (request.headers["x"]), the
+// string functions REGEXP_EXTRACT, EXTRACT and to_lower, and constant
+// string/bool comparisons via ==. It does not attempt full expression
+// parsing; it recognizes exactly the forms listed above, as used in
+// ValueExtractor and LabelExtractors.
+func compileExpr(expr string) (func(bag AttributeBag) (interface{}, error), error) {
+	expr = strings.TrimSpace(expr)
+
+	if lhs, rhs, ok := splitTopLevelEq(expr); ok {
+		left, err := compileExpr(lhs)
+		if err != nil {
+			return nil, err
+		}
+		right, err := compileExpr(rhs)
+		if err != nil {
+			return nil, err
+		}
+		return func(bag AttributeBag) (interface{}, error) {
+			l, err := left(bag)
+			if err != nil {
+				return nil, err
+			}
+			r, err := right(bag)
+			if err != nil {
+				return nil, err
+			}
+			return l == r, nil
+		}, nil
+	}
+
+	if expr == "true" {
+		return func(AttributeBag) (interface{}, error) { return true, nil }, nil
+	}
+	if expr == "false" {
+		return func(AttributeBag) (interface{}, error) { return false, nil }, nil
+	}
+
+	if m := regexpExtractCall.FindStringSubmatch(expr); m != nil {
+		inner, err := compileExpr(m[1])
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(unescapeExprString(m[2]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid REGEXP_EXTRACT pattern %q: %v", m[2], err)
+		}
+		return func(bag AttributeBag) (interface{}, error) {
+			v, err := inner(bag)
+			if err != nil {
+				return nil, err
+			}
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("REGEXP_EXTRACT requires a string operand, got %T", v)
+			}
+			groups := re.FindStringSubmatch(s)
+			if groups == nil {
+				return "", nil
+			}
+			if len(groups) > 1 {
+				return groups[1], nil
+			}
+			return groups[0], nil
+		}, nil
+	}
+
+	if m := extractCall.FindStringSubmatch(expr); m != nil {
+		inner, err := compileExpr(m[1])
+		if err != nil {
+			return nil, err
+		}
+		key := unescapeExprString(m[2])
+		return func(bag AttributeBag) (interface{}, error) {
+			v, err := inner(bag)
+			if err != nil {
+				return nil, err
+			}
+			m, ok := v.(map[string]string)
+			if !ok {
+				return nil, fmt.Errorf("EXTRACT requires a string map operand, got %T", v)
+			}
+			return m[key], nil
+		}, nil
+	}
+
+	if m := toLowerCall.FindStringSubmatch(expr); m != nil {
+		inner, err := compileExpr(m[1])
+		if err != nil {
+			return nil, err
+		}
+		return func(bag AttributeBag) (interface{}, error) {
+			v, err := inner(bag)
+			if err != nil {
+				return nil, err
+			}
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("to_lower requires a string operand, got %T", v)
+			}
+			return strings.ToLower(s), nil
+		}, nil
+	}
+
+	if strings.HasPrefix(expr, `"`) && strings.HasSuffix(expr, `"`) && len(expr) >= 2 {
+		s := unescapeExprString(expr[1 : len(expr)-1])
+		return func(AttributeBag) (interface{}, error) { return s, nil }, nil
+	}
+
+	name, key, hasKey := splitFieldAccess(expr)
+	return func(bag AttributeBag) (interface{}, error) {
+		v, found := bag.Get(name)
+		if !found {
+			return nil, nil
+		}
+		if !hasKey {
+			return v, nil
+		}
+		m, ok := v.(map[string]string)
+		if !ok {
+			return nil, fmt.Errorf("%q is not a string map attribute", name)
+		}
+		return m[key], nil
+	}, nil
+}
+
+// splitTopLevelEq splits expr on a top-level "==", i.e. one that does not
+// appear inside a quoted string literal, returning its trimmed operands.
+// Comparisons do not nest, so a single split is sufficient.
+func splitTopLevelEq(expr string) (lhs, rhs string, ok bool) {
+	inQuotes := false
+	for i := 0; i < len(expr)-1; i++ {
+		switch {
+		case expr[i] == '\\' && inQuotes:
+			i++
+		case expr[i] == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && expr[i] == '=' && expr[i+1] == '=':
+			return strings.TrimSpace(expr[:i]), strings.TrimSpace(expr[i+2:]), true
+		}
+	}
+	return "", "", false
+}
+
+// splitFieldAccess parses `name` or `name["key"]` attribute references.
+func splitFieldAccess(expr string) (name, key string, hasKey bool) {
+	open := strings.Index(expr, "[")
+	if open < 0 || !strings.HasSuffix(expr, "]") {
+		return expr, "", false
+	}
+	name = expr[:open]
+	key = strings.Trim(expr[open+1:len(expr)-1], `"`)
+	return name, key, true
+}
+
+func unescapeExprString(s string) string {
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(s)
+}
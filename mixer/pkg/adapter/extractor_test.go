@@ -0,0 +1,116 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import "testing"
+
+type testBag map[string]interface{}
+
+func (b testBag) Get(name string) (interface{}, bool) {
+	v, ok := b[name]
+	return v, ok
+}
+
+func TestCompileValueExtractorAcceptsNumericKinds(t *testing.T) {
+	c := NewExtractorCompiler()
+	types := map[string]LabelType{"request.size": Int64Label}
+
+	fn, err := c.CompileValueExtractor("request.size", types, Counter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	v, err := fn(testBag{"request.size": int64(5)})
+	if err != nil || v != int64(5) {
+		t.Fatalf("got (%v, %v), want (5, nil)", v, err)
+	}
+}
+
+func TestCompileValueExtractorRejectsNonNumeric(t *testing.T) {
+	c := NewExtractorCompiler()
+	types := map[string]LabelType{"request.path": StringLabel}
+
+	if _, err := c.CompileValueExtractor("request.path", types, Counter); err == nil {
+		t.Fatal("expected a type error for a string-valued extractor on a Counter")
+	}
+}
+
+func TestCompileLabelExtractorTypeMismatch(t *testing.T) {
+	c := NewExtractorCompiler()
+	types := map[string]LabelType{"request.headers": StringMapLabel}
+
+	if _, err := c.CompileLabelExtractor(`EXTRACT(request.headers, "x-foo")`, types, Int64Label); err == nil {
+		t.Fatal("expected a type mismatch error (EXTRACT produces a string)")
+	}
+}
+
+func TestCompileLabelExtractorEvaluatesExtract(t *testing.T) {
+	c := NewExtractorCompiler()
+	types := map[string]LabelType{"request.headers": StringMapLabel}
+
+	fn, err := c.CompileLabelExtractor(`EXTRACT(request.headers, "x-foo")`, types, StringLabel)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	v, err := fn(testBag{"request.headers": map[string]string{"x-foo": "bar"}})
+	if err != nil || v != "bar" {
+		t.Fatalf("got (%v, %v), want (bar, nil)", v, err)
+	}
+}
+
+func TestCompileExtractorRejectsUndeclaredAttribute(t *testing.T) {
+	c := NewExtractorCompiler()
+
+	if _, err := c.CompileLabelExtractor("request.unknown", nil, StringLabel); err == nil {
+		t.Fatal("expected an error for an attribute absent from labelTypes")
+	}
+}
+
+func TestCompileLabelExtractorEvaluatesStringComparison(t *testing.T) {
+	c := NewExtractorCompiler()
+	types := map[string]LabelType{"request.path": StringLabel}
+
+	fn, err := c.CompileLabelExtractor(`request.path == "/healthz"`, types, BoolLabel)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, err := fn(testBag{"request.path": "/healthz"}); err != nil || v != true {
+		t.Fatalf("got (%v, %v), want (true, nil)", v, err)
+	}
+	if v, err := fn(testBag{"request.path": "/other"}); err != nil || v != false {
+		t.Fatalf("got (%v, %v), want (false, nil)", v, err)
+	}
+}
+
+func TestCompileLabelExtractorEvaluatesBoolComparison(t *testing.T) {
+	c := NewExtractorCompiler()
+	types := map[string]LabelType{"request.debug": BoolLabel}
+
+	fn, err := c.CompileLabelExtractor("request.debug == true", types, BoolLabel)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, err := fn(testBag{"request.debug": true}); err != nil || v != true {
+		t.Fatalf("got (%v, %v), want (true, nil)", v, err)
+	}
+}
+
+func TestCompileExtractorRejectsMixedTypeComparison(t *testing.T) {
+	c := NewExtractorCompiler()
+	types := map[string]LabelType{"request.size": Int64Label}
+
+	if _, err := c.CompileLabelExtractor(`request.size == "5"`, types, BoolLabel); err == nil {
+		t.Fatal("expected a type error comparing an int64 attribute to a string constant")
+	}
+}
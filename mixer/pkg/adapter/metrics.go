@@ -17,6 +17,8 @@ package adapter
 import (
 	"errors"
 	"time"
+
+	"istio.io/istio/mixer/pkg/adapter/metrics"
 )
 
 // Metric kinds supported by mixer.
@@ -26,6 +28,17 @@ const (
 	Distribution                   // aggregates values in buckets (values still reported un-aggregated)
 )
 
+// Label types supported by mixer, describing the Go-level type of a
+// MetricDefinition.Labels entry or an attribute consulted by the extractor
+// expression language (see ExtractorCompiler).
+const (
+	StringLabel    LabelType = iota // string-valued
+	Int64Label                      // int64-valued
+	DoubleLabel                     // float64-valued
+	BoolLabel                       // bool-valued
+	StringMapLabel                  // map[string]string-valued, e.g. request headers
+)
+
 type (
 	// MetricsAspect handles metric reporting within the mixer.
 	MetricsAspect interface {
@@ -56,18 +69,115 @@ type (
 
 		// The value of this metric; this should be accessed type-safely via value.String(), value.Bool(), etc.
 		MetricValue interface{}
+
+		// Exemplars holds representative raw observations for trace-to-metric
+		// correlation. It is only populated when the backend reports
+		// MetricsBuilder.SupportsExemplars() == true. For Distribution
+		// values, each exemplar logically belongs to the bucket containing
+		// its Value.
+		Exemplars []Exemplar
+	}
+
+	// Exemplar is a single representative observation attached to a metric
+	// value (typically a Distribution bucket) that can be correlated back
+	// to the trace or span it was recorded during.
+	Exemplar struct {
+		// Value is the raw observation this exemplar represents.
+		Value float64
+		// Timestamp is when the observation was recorded.
+		Timestamp time.Time
+		// TraceID identifies the trace the observation was recorded during.
+		TraceID string
+		// SpanID identifies the span the observation was recorded during.
+		SpanID string
+		// Labels carries any additional dimensional data attached to the
+		// exemplar beyond the metric's own Labels.
+		Labels map[string]string
+	}
+
+	// DistributionValue carries a pre-aggregated set of bucket counts for a
+	// Distribution-kind metric, along with the summary statistics needed to
+	// compute an average without re-reading every bucket. Counts is aligned
+	// to the Buckets on the corresponding MetricDefinition: it has Count+2
+	// entries for LinearBuckets/ExponentialBuckets, and len(Bounds)+1 entries
+	// for ExplicitBuckets. Counts[0] holds the underflow bucket (values below
+	// the first finite bound) and Counts[len(Counts)-1] holds the overflow
+	// bucket (values at or above the last finite bound).
+	DistributionValue struct {
+		// Counts holds the number of observations that fell into each
+		// bucket, in the same order as the MetricDefinition's Buckets.
+		Counts []uint64
+		// Sum is the sum of all observations that were aggregated into
+		// Counts.
+		Sum float64
+		// SampleCount is the total number of observations aggregated into
+		// Counts; it is equal to the sum of Counts.
+		SampleCount uint64
+	}
+
+	// SparseDistributionValue carries the sparse, auto-resizing exponential
+	// histogram encoding produced for a NativeExponentialBuckets metric.
+	// Unlike DistributionValue, buckets are keyed by signed integer index
+	// rather than stored in a dense slice, since only a small fraction of
+	// the representable index range is typically populated.
+	SparseDistributionValue struct {
+		// Scale is the resolution the buckets were recorded at; see
+		// NativeExponentialBuckets.Scale. It may be lower than the
+		// metric's originally configured Scale if the mixer halved
+		// resolution to stay within MaxBuckets.
+		Scale int32
+		// ZeroCount is the number of observations within
+		// NativeExponentialBuckets.ZeroThreshold of zero.
+		ZeroCount uint64
+		// PositiveBuckets maps bucket index to observation count for
+		// values >= ZeroThreshold.
+		PositiveBuckets map[int32]uint64
+		// NegativeBuckets maps bucket index to observation count for
+		// values <= -ZeroThreshold, indexed by the bucket index of their
+		// absolute value.
+		NegativeBuckets map[int32]uint64
 	}
 
 	// MetricKind defines the set of known metrics types that can be generated
 	// by the mixer.
 	MetricKind int
 
+	// LabelType describes the Go-level type of a label or of an attribute
+	// the extractor expression language may read.
+	LabelType int
+
 	// MetricsBuilder builds instances of the Metrics aspect.
 	MetricsBuilder interface {
 		Builder
 
 		// NewMetricsAspect returns a new instance of the Metrics aspect.
 		NewMetricsAspect(env Env, config Config, metrics map[string]*MetricDefinition) (MetricsAspect, error)
+
+		// AcceptsPreAggregatedDistributions reports whether the backend this
+		// builder constructs aspects for can consume Value.Distribution()
+		// directly. When false, the mixer synthesizes per-observation Values
+		// instead of forwarding DistributionValue unchanged.
+		AcceptsPreAggregatedDistributions() bool
+
+		// SupportsExemplars reports whether the backend this builder
+		// constructs aspects for can carry Value.Exemplars. When false, the
+		// mixer does not attach exemplars to the Values it generates.
+		SupportsExemplars() bool
+
+		// AcceptsSparseDistributions reports whether the backend this
+		// builder constructs aspects for can consume
+		// Value.SparseDistribution() directly. When false, the mixer
+		// falls back to converting NativeExponentialBuckets observations
+		// into a DistributionValue against DefaultExplicitBounds before
+		// recording them.
+		AcceptsSparseDistributions() bool
+
+		// DefaultExplicitBounds returns the explicit bucket boundaries used
+		// to convert a NativeExponentialBuckets metric's observations for a
+		// backend that returns false from AcceptsSparseDistributions, in
+		// the spirit of the OTel SDK's WithExplicitBucketBoundaries view
+		// option. It is only consulted in that fallback case.
+		DefaultExplicitBounds() []float64
 	}
 
 	// MetricDefinition provides the basic description of a metric schema
@@ -81,11 +191,43 @@ type (
 		Description string
 		// Kind provides type information about the metric.
 		Kind MetricKind
+		// Unit is the unit of measurement for this metric's values, using
+		// UCUM-style abbreviations such as "s", "By", or "1", for
+		// adapter-side formatting (e.g. Prometheus HELP/TYPE lines).
+		Unit string
 		// Labels are the names of keys for dimensional data that will
 		// be generated at runtime and passed along with metric values.
 		Labels map[string]LabelType
 
 		Buckets BucketDefinition
+
+		// ValueExtractor is an optional mixer-attribute expression (see
+		// ExtractorCompiler) that is evaluated per Report() to produce
+		// this metric's Value.MetricValue, instead of requiring the
+		// operator-authored rule to compute and pass the value directly.
+		ValueExtractor string
+
+		// LabelExtractors maps label name to a mixer-attribute expression
+		// (see ExtractorCompiler) that is evaluated per Report() to
+		// produce that label's value in Value.Labels. Keys must be a
+		// subset of the keys in Labels.
+		LabelExtractors map[string]string
+	}
+
+	// MetricsRegistry exposes the canonical set of metric Descriptions the
+	// mixer currently knows about, aggregated across the default built-in
+	// metrics and anything registered by adapters at Validate-time. It is
+	// available to adapters through Env so they can enumerate metrics
+	// without duplicating adapter config (e.g. a Prometheus adapter
+	// emitting `# HELP`/`# TYPE` lines, or a `/metricsz` debug endpoint).
+	MetricsRegistry interface {
+		// All returns every metric Description currently known to the
+		// mixer.
+		All() []metrics.Description
+
+		// Lookup returns the Description for the named metric, and false
+		// if no such metric is known.
+		Lookup(name string) (metrics.Description, bool)
 	}
 
 	// BucketDefinition provides a common interface for the various types
@@ -160,6 +302,32 @@ type (
 		// monotonically increasing values.
 		Bounds []float64
 	}
+
+	// NativeExponentialBuckets describes a sparse, auto-resizing
+	// exponential bucket layout that does not require pre-declaring the
+	// number of buckets. Bucket `i` covers `[base^i, base^(i+1))`, where
+	// `base = 2^(2^-Scale)`; values in `(-ZeroThreshold, ZeroThreshold)`
+	// are counted as zero rather than assigned a bucket. The mixer grows
+	// the set of observed buckets on demand, and on exceeding MaxBuckets
+	// halves resolution by decrementing Scale and merging adjacent
+	// buckets pairwise (see SparseHistogram).
+	NativeExponentialBuckets struct {
+		BucketDefinition
+
+		// Scale controls the resolution of the layout: higher Scale means
+		// narrower buckets. Buckets double in width for every decrement
+		// of Scale.
+		Scale int32
+
+		// MaxBuckets bounds the number of distinct buckets the mixer will
+		// track per series before halving resolution.
+		MaxBuckets int32
+
+		// ZeroThreshold is the half-width of the zero bucket: values with
+		// absolute value less than ZeroThreshold are counted in ZeroCount
+		// instead of a positive or negative bucket.
+		ZeroThreshold float64
+	}
 )
 
 // String returns the string-valued metric value for a metrics.Value.
@@ -193,3 +361,21 @@ func (v Value) Float64() (float64, error) {
 	}
 	return 0, errors.New("metric value is not a float64")
 }
+
+// Distribution returns the pre-aggregated distribution value for a
+// metrics.Value.
+func (v Value) Distribution() (*DistributionValue, error) {
+	if v, ok := v.MetricValue.(*DistributionValue); ok {
+		return v, nil
+	}
+	return nil, errors.New("metric value is not a distribution")
+}
+
+// SparseDistribution returns the sparse exponential histogram value for a
+// metrics.Value recorded against a NativeExponentialBuckets definition.
+func (v Value) SparseDistribution() (*SparseDistributionValue, error) {
+	if v, ok := v.MetricValue.(*SparseDistributionValue); ok {
+		return v, nil
+	}
+	return nil, errors.New("metric value is not a sparse distribution")
+}
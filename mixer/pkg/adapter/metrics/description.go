@@ -0,0 +1,57 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics defines the stable, discoverable schema of metrics the
+// mixer knows about, independent of any particular backend adapter. It is
+// modeled after Go's runtime/metrics package: a metric's Description is
+// data, not behavior, so it can be enumerated, serialized, and diffed
+// without pulling in adapter implementations.
+package metrics
+
+// ValueKind describes the Go-level representation of a metric's values.
+type ValueKind int
+
+const (
+	// Int64Value indicates the metric's values are int64.
+	Int64Value ValueKind = iota
+	// Float64Value indicates the metric's values are float64.
+	Float64Value
+	// DistributionValue indicates the metric's values are pre-aggregated
+	// or per-observation distributions.
+	DistributionValue
+)
+
+// Description is the immutable, discoverable schema for a single metric
+// known to the mixer. It carries no behavior; adapters and debug endpoints
+// consume it to describe the metrics they can report without reading
+// adapter-specific config.
+type Description struct {
+	// Name is the canonical name of the metric, matching the
+	// corresponding adapter.MetricDefinition.Name.
+	Name string
+	// Description is a short, human-readable explanation of what the
+	// metric measures.
+	Description string
+	// Kind is the metric's kind: Gauge, Counter, or Distribution.
+	Kind int
+	// Unit is the unit of measurement for the metric's values, using the
+	// same conventions as adapter.MetricDefinition.Unit (e.g. "s", "By").
+	Unit string
+	// Cumulative reports whether the metric's values accumulate over the
+	// lifetime of the process (true for Counter) or are reset between
+	// reporting periods.
+	Cumulative bool
+	// ValueKind describes the Go-level type of the metric's values.
+	ValueKind ValueKind
+}
@@ -0,0 +1,95 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opentelemetry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// batchingClient buffers dataPoints and flushes them to an underlying OTel
+// SDK metric exporter (the gRPC or HTTP OTLP exporter) either when
+// cfg.BatchSize is reached or every cfg.ExportInterval, whichever comes
+// first. The mixer calls Record/Export synchronously per-request, so
+// batching here is what keeps export frequency decoupled from request rate.
+type batchingClient struct {
+	cfg      *Config
+	exporter sdkmetric.Exporter
+
+	mu      sync.Mutex
+	pending []dataPoint
+
+	ticker *time.Ticker
+	done   chan struct{}
+}
+
+func newBatchingClient(cfg *Config, exporter sdkmetric.Exporter) *batchingClient {
+	c := &batchingClient{
+		cfg:      cfg,
+		exporter: exporter,
+		ticker:   time.NewTicker(cfg.ExportInterval),
+		done:     make(chan struct{}),
+	}
+	go c.flushLoop()
+	return c
+}
+
+func (c *batchingClient) Export(points []dataPoint) error {
+	c.mu.Lock()
+	c.pending = append(c.pending, points...)
+	shouldFlush := len(c.pending) >= c.cfg.BatchSize
+	c.mu.Unlock()
+
+	if shouldFlush {
+		return c.flush()
+	}
+	return nil
+}
+
+func (c *batchingClient) flushLoop() {
+	for {
+		select {
+		case <-c.ticker.C:
+			_ = c.flush()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *batchingClient) flush() error {
+	c.mu.Lock()
+	if len(c.pending) == 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	batch := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.ExportInterval)
+	defer cancel()
+	return c.exporter.Export(ctx, toResourceMetrics(batch, c.cfg.ResourceAttributes))
+}
+
+func (c *batchingClient) Close() error {
+	close(c.done)
+	c.ticker.Stop()
+	_ = c.flush()
+	return c.exporter.Shutdown(context.Background())
+}
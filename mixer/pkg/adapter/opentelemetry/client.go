@@ -0,0 +1,32 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opentelemetry
+
+import "fmt"
+
+// newOTLPClient constructs the gRPC or HTTP OTLP exporter client described
+// by cfg. Batching and periodic export on cfg.ExportInterval are handled
+// inside each client; Export enqueues points for the next flush, and once
+// cfg.BatchSize points are pending it flushes them before returning.
+func newOTLPClient(cfg *Config) (otlpClient, error) {
+	switch cfg.Protocol {
+	case ProtocolGRPC:
+		return newGRPCClient(cfg)
+	case ProtocolHTTP:
+		return newHTTPClient(cfg)
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %v", cfg.Protocol)
+	}
+}
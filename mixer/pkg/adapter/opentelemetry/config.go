@@ -0,0 +1,87 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opentelemetry
+
+import "time"
+
+// Temporality controls whether counters are exported as running totals or
+// as the delta since the previous export.
+type Temporality int
+
+const (
+	// Cumulative exports each counter as the running total since the
+	// process started, matching Prometheus/Stackdriver convention.
+	Cumulative Temporality = iota
+	// Delta exports each counter as the change since the previous export,
+	// as required by some OTLP backends. Requires the mixer to track
+	// per-series previous values; see deltaTracker.
+	Delta
+)
+
+// Protocol selects the OTLP transport used to reach the collector.
+type Protocol int
+
+const (
+	// ProtocolGRPC exports over OTLP/gRPC.
+	ProtocolGRPC Protocol = iota
+	// ProtocolHTTP exports over OTLP/HTTP.
+	ProtocolHTTP
+)
+
+// TLSConfig describes the client TLS settings used to reach the collector.
+type TLSConfig struct {
+	// Insecure disables TLS entirely (plaintext OTLP).
+	Insecure bool
+	// CACertFile, CertFile, and KeyFile are PEM file paths used to
+	// establish a mutual-TLS connection to the collector. CACertFile may
+	// be set alone for server-auth-only TLS.
+	CACertFile string
+	CertFile   string
+	KeyFile    string
+	// ServerName overrides the collector's expected TLS server name.
+	ServerName string
+}
+
+// Config is the operator-facing configuration for the opentelemetry
+// adapter.
+type Config struct {
+	// Endpoint is the host:port of the OTLP collector to export to.
+	Endpoint string
+	// Protocol selects OTLP/gRPC or OTLP/HTTP.
+	Protocol Protocol
+	// TLS holds the client TLS settings used to reach Endpoint.
+	TLS TLSConfig
+	// BatchSize is the maximum number of data points buffered before a
+	// forced export.
+	BatchSize int
+	// ExportInterval is the maximum time between exports, regardless of
+	// whether BatchSize has been reached.
+	ExportInterval time.Duration
+	// ResourceAttributes are attached to every exported metric as OTel
+	// resource attributes (e.g. service.name, service.namespace).
+	ResourceAttributes map[string]string
+	// Temporality selects cumulative or delta counter export.
+	Temporality Temporality
+}
+
+// DefaultConfig returns the adapter's default configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Protocol:       ProtocolGRPC,
+		BatchSize:      512,
+		ExportInterval: 10 * time.Second,
+		Temporality:    Cumulative,
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opentelemetry
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"istio.io/istio/mixer/pkg/adapter"
+)
+
+// toResourceMetrics packages a batch of translated dataPoints into the
+// metricdata.ResourceMetrics shape the OTel SDK's OTLP exporters accept,
+// tagging the resource with the operator-configured attributes.
+func toResourceMetrics(points []dataPoint, resourceAttrs map[string]string) *metricdata.ResourceMetrics {
+	attrs := make([]attribute.KeyValue, 0, len(resourceAttrs))
+	for k, v := range resourceAttrs {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	metrics := make([]metricdata.Metrics, 0, len(points))
+	for _, dp := range points {
+		metrics = append(metrics, toMetrics(dp))
+	}
+
+	return &metricdata.ResourceMetrics{
+		Resource: resource.NewSchemaless(attrs...),
+		ScopeMetrics: []metricdata.ScopeMetrics{
+			{Metrics: metrics},
+		},
+	}
+}
+
+func toMetrics(dp dataPoint) metricdata.Metrics {
+	attrSet := attribute.NewSet(toAttributeKVs(dp.Attributes)...)
+	start := time.Unix(0, dp.StartTime)
+	end := time.Unix(0, dp.Time)
+
+	switch dp.Kind {
+	case sumPoint:
+		return metricdata.Metrics{
+			Name: dp.Name,
+			Unit: dp.Unit,
+			Data: metricdata.Sum[float64]{
+				Temporality: toOTelTemporality(dp.Temporality),
+				IsMonotonic: dp.IsMonotonic,
+				DataPoints: []metricdata.DataPoint[float64]{
+					{Attributes: attrSet, StartTime: start, Time: end, Value: dp.SumValue},
+				},
+			},
+		}
+	case histogramPoint:
+		return metricdata.Metrics{
+			Name: dp.Name,
+			Unit: dp.Unit,
+			Data: metricdata.Histogram[float64]{
+				Temporality: metricdata.CumulativeTemporality,
+				DataPoints: []metricdata.HistogramDataPoint[float64]{
+					{
+						Attributes:   attrSet,
+						StartTime:    start,
+						Time:         end,
+						Count:        dp.Count,
+						Sum:          dp.Sum,
+						Bounds:       dp.Bounds,
+						BucketCounts: dp.Counts,
+					},
+				},
+			},
+		}
+	default: // gaugePoint
+		return metricdata.Metrics{
+			Name: dp.Name,
+			Unit: dp.Unit,
+			Data: metricdata.Gauge[float64]{
+				DataPoints: []metricdata.DataPoint[float64]{
+					{Attributes: attrSet, StartTime: start, Time: end, Value: dp.GaugeValue},
+				},
+			},
+		}
+	}
+}
+
+func toAttributeKVs(kvs []adapter.KeyValue) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		switch v := kv.Value.(type) {
+		case string:
+			out = append(out, attribute.String(kv.Key, v))
+		case bool:
+			out = append(out, attribute.Bool(kv.Key, v))
+		case int64:
+			out = append(out, attribute.Int64(kv.Key, v))
+		case float64:
+			out = append(out, attribute.Float64(kv.Key, v))
+		default:
+			out = append(out, attribute.String(kv.Key, stringify(v)))
+		}
+	}
+	return out
+}
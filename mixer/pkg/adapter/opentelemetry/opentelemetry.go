@@ -0,0 +1,126 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package opentelemetry adapts the mixer's metrics aspect to the
+// OpenTelemetry Protocol (OTLP), exporting to a configurable collector over
+// gRPC or HTTP. It is intentionally independent of any particular backend:
+// the collector is expected to fan out to whatever observability systems
+// the operator runs.
+package opentelemetry
+
+import (
+	"fmt"
+
+	"istio.io/istio/mixer/pkg/adapter"
+)
+
+type (
+	builder struct {
+		adapterCfg *Config
+	}
+
+	aspect struct {
+		env    adapter.Env
+		cfg    *Config
+		client otlpClient
+		deltas *deltaTracker
+	}
+
+	// otlpClient is the narrow interface onto the OTLP export path that
+	// aspect.Record needs; batchingClient implements it around either the
+	// gRPC or the HTTP exporter.
+	otlpClient interface {
+		Export(points []dataPoint) error
+		Close() error
+	}
+)
+
+// NewBuilder returns the builder for the opentelemetry adapter, seeded with
+// its default configuration.
+func NewBuilder() adapter.MetricsBuilder {
+	return &builder{adapterCfg: DefaultConfig()}
+}
+
+func (b *builder) Name() string { return "opentelemetry" }
+
+func (b *builder) Description() string {
+	return "Exports mixer metrics to an OpenTelemetry Protocol (OTLP) collector"
+}
+
+func (b *builder) DefaultConfig() adapter.Config { return b.adapterCfg }
+
+func (b *builder) Validate() (ce *adapter.ConfigErrors) {
+	if b.adapterCfg.Endpoint == "" {
+		ce = ce.Append("endpoint", fmt.Errorf("endpoint is required"))
+	}
+	if b.adapterCfg.BatchSize <= 0 {
+		ce = ce.Append("batchSize", fmt.Errorf("batchSize must be greater than 0"))
+	}
+	if b.adapterCfg.ExportInterval <= 0 {
+		ce = ce.Append("exportInterval", fmt.Errorf("exportInterval must be greater than 0"))
+	}
+	return ce
+}
+
+func (b *builder) Close() error { return nil }
+
+func (b *builder) AcceptsPreAggregatedDistributions() bool { return true }
+
+func (b *builder) SupportsExemplars() bool { return false }
+
+func (b *builder) AcceptsSparseDistributions() bool { return false }
+
+func (b *builder) DefaultExplicitBounds() []float64 {
+	return []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+}
+
+// NewMetricsAspect returns a new instance of the Metrics aspect that
+// exports to the collector described by config.
+func (b *builder) NewMetricsAspect(env adapter.Env, config adapter.Config, _ map[string]*adapter.MetricDefinition) (adapter.MetricsAspect, error) {
+	cfg := config.(*Config)
+
+	client, err := newOTLPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("opentelemetry: failed to build OTLP client: %v", err)
+	}
+
+	return &aspect{
+		env:    env,
+		cfg:    cfg,
+		client: client,
+		deltas: newDeltaTracker(),
+	}, nil
+}
+
+// Record translates each reported Value into an OTLP data point and
+// forwards the batch to the configured collector.
+func (a *aspect) Record(values []adapter.Value) error {
+	points := make([]dataPoint, 0, len(values))
+	for _, v := range values {
+		dp, err := translate(v, a.deltas, a.cfg.Temporality)
+		if err != nil {
+			a.env.Logger().Errorf("opentelemetry: dropping value for %q: %v", v.Definition.Name, err)
+			continue
+		}
+		points = append(points, dp)
+	}
+	if len(points) == 0 {
+		return nil
+	}
+	return a.client.Export(points)
+}
+
+func (a *aspect) Close() error {
+	return a.client.Close()
+}
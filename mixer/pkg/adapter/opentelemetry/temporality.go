@@ -0,0 +1,61 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opentelemetry
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// toOTelTemporality maps this adapter's Temporality onto the OTel SDK's
+// metricdata.Temporality, so the exported Sum's Temporality field always
+// matches the actual values translate produced for it.
+func toOTelTemporality(t Temporality) metricdata.Temporality {
+	if t == Delta {
+		return metricdata.DeltaTemporality
+	}
+	return metricdata.CumulativeTemporality
+}
+
+// deltaTracker converts cumulative counter values (the only form the mixer
+// ever synthesizes) into deltas since the previous export, for adapters
+// configured with Temporality == Delta. It is safe for concurrent use from
+// Record().
+type deltaTracker struct {
+	mu   sync.Mutex
+	prev map[string]float64
+}
+
+func newDeltaTracker() *deltaTracker {
+	return &deltaTracker{prev: make(map[string]float64)}
+}
+
+// Delta returns the change in value for the series identified by key since
+// the last call with that key, and records current as the new baseline. The
+// first observation of a series returns current unchanged, and a decrease
+// (e.g. a counter reset) is treated as a fresh baseline rather than a
+// negative delta.
+func (t *deltaTracker) Delta(key string, current float64) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	last, ok := t.prev[key]
+	t.prev[key] = current
+	if !ok || current < last {
+		return current
+	}
+	return current - last
+}
@@ -0,0 +1,120 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opentelemetry
+
+import (
+	"fmt"
+
+	"istio.io/istio/mixer/pkg/adapter"
+)
+
+// pointKind mirrors the three OTLP metric data point shapes this adapter
+// emits: gauges, monotonic sums, and histograms.
+type pointKind int
+
+const (
+	gaugePoint pointKind = iota
+	sumPoint
+	histogramPoint
+)
+
+// dataPoint is this adapter's backend-agnostic view of a single exported
+// OTLP point, built from an adapter.Value. aspect.Record hands these to the
+// OTLP/gRPC or OTLP/HTTP client.
+type dataPoint struct {
+	Kind        pointKind
+	Name        string
+	Unit        string
+	Attributes  []adapter.KeyValue
+	StartTime   int64
+	Time        int64
+	GaugeValue  float64
+	SumValue    float64
+	IsMonotonic bool
+	Temporality Temporality
+	Bounds      []float64
+	Counts      []uint64
+	Sum         float64
+	Count       uint64
+}
+
+// numericValue returns v's value as a float64, accepting either an int64-
+// or float64-valued metric.
+func numericValue(v adapter.Value) (float64, error) {
+	if i, err := v.Int64(); err == nil {
+		return float64(i), nil
+	}
+	return v.Float64()
+}
+
+// translate converts a single adapter.Value into the dataPoint this
+// adapter's exporter understands, consulting deltas for Sum-kind metrics
+// configured for delta temporality.
+func translate(v adapter.Value, deltas *deltaTracker, temporality Temporality) (dataPoint, error) {
+	dp := dataPoint{
+		Name:       v.Definition.Name,
+		Unit:       v.Definition.Unit,
+		Attributes: adapter.ToKeyValues(v.Labels),
+		StartTime:  v.StartTime.UnixNano(),
+		Time:       v.EndTime.UnixNano(),
+	}
+
+	switch v.Definition.Kind {
+	case adapter.Gauge:
+		f, err := numericValue(v)
+		if err != nil {
+			return dataPoint{}, err
+		}
+		dp.Kind = gaugePoint
+		dp.GaugeValue = f
+
+	case adapter.Counter:
+		f, err := numericValue(v)
+		if err != nil {
+			return dataPoint{}, err
+		}
+		dp.Kind = sumPoint
+		dp.IsMonotonic = true
+		dp.Temporality = temporality
+		if temporality == Delta {
+			f = deltas.Delta(adapter.SeriesKey(v.Definition.Name, v.Labels), f)
+		}
+		dp.SumValue = f
+
+	case adapter.Distribution:
+		dist, err := v.Distribution()
+		if err != nil {
+			return dataPoint{}, err
+		}
+		var bounds []float64
+		if _, ok := v.Definition.Buckets.(adapter.NativeExponentialBuckets); ok {
+			// This adapter does not accept sparse distributions, so their
+			// counts arrive converted against DefaultExplicitBounds.
+			bounds = (&builder{}).DefaultExplicitBounds()
+		} else if bounds, err = adapter.Bounds(v.Definition.Buckets); err != nil {
+			return dataPoint{}, err
+		}
+		dp.Kind = histogramPoint
+		dp.Bounds = bounds
+		dp.Counts = dist.Counts
+		dp.Sum = dist.Sum
+		dp.Count = dist.SampleCount
+
+	default:
+		return dataPoint{}, fmt.Errorf("opentelemetry: unsupported metric kind %v for %q", v.Definition.Kind, v.Definition.Name)
+	}
+
+	return dp, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opentelemetry
+
+import (
+	"testing"
+	"time"
+
+	"istio.io/istio/mixer/pkg/adapter"
+)
+
+func gaugeValue(metricValue interface{}) adapter.Value {
+	return adapter.Value{
+		Definition:  &adapter.MetricDefinition{Name: "g", Kind: adapter.Gauge},
+		MetricValue: metricValue,
+		StartTime:   time.Unix(0, 0),
+		EndTime:     time.Unix(0, 0),
+	}
+}
+
+func counterValue(metricValue interface{}) adapter.Value {
+	return adapter.Value{
+		Definition:  &adapter.MetricDefinition{Name: "c", Kind: adapter.Counter},
+		MetricValue: metricValue,
+		StartTime:   time.Unix(0, 0),
+		EndTime:     time.Unix(0, 0),
+	}
+}
+
+func TestTranslateGaugeAcceptsInt64AndFloat64(t *testing.T) {
+	for _, v := range []adapter.Value{gaugeValue(int64(42)), gaugeValue(float64(42))} {
+		dp, err := translate(v, newDeltaTracker(), Cumulative)
+		if err != nil {
+			t.Fatalf("translate(%v) returned error: %v", v.MetricValue, err)
+		}
+		if dp.Kind != gaugePoint {
+			t.Errorf("Kind = %v, want gaugePoint", dp.Kind)
+		}
+		if dp.GaugeValue != 42 {
+			t.Errorf("GaugeValue = %v, want 42", dp.GaugeValue)
+		}
+	}
+}
+
+func TestTranslateCounterCumulativeTemporality(t *testing.T) {
+	deltas := newDeltaTracker()
+
+	dp, err := translate(counterValue(int64(10)), deltas, Cumulative)
+	if err != nil {
+		t.Fatalf("translate returned error: %v", err)
+	}
+	if dp.Temporality != Cumulative {
+		t.Errorf("Temporality = %v, want Cumulative", dp.Temporality)
+	}
+	if dp.SumValue != 10 {
+		t.Errorf("SumValue = %v, want 10 (cumulative passes the raw value through)", dp.SumValue)
+	}
+}
+
+func TestTranslateCounterDeltaTemporality(t *testing.T) {
+	deltas := newDeltaTracker()
+
+	first, err := translate(counterValue(float64(10)), deltas, Delta)
+	if err != nil {
+		t.Fatalf("translate returned error: %v", err)
+	}
+	if first.Temporality != Delta {
+		t.Errorf("Temporality = %v, want Delta", first.Temporality)
+	}
+	if first.SumValue != 10 {
+		t.Errorf("first SumValue = %v, want 10 (no prior baseline)", first.SumValue)
+	}
+
+	second, err := translate(counterValue(float64(15)), deltas, Delta)
+	if err != nil {
+		t.Fatalf("translate returned error: %v", err)
+	}
+	if second.SumValue != 5 {
+		t.Errorf("second SumValue = %v, want 5 (15-10 delta)", second.SumValue)
+	}
+}
+
+func TestTranslateUnsupportedKind(t *testing.T) {
+	v := adapter.Value{
+		Definition: &adapter.MetricDefinition{Name: "bad", Kind: adapter.MetricKind(99)},
+	}
+	if _, err := translate(v, newDeltaTracker(), Cumulative); err == nil {
+		t.Error("expected an error for an unsupported metric kind, got nil")
+	}
+}
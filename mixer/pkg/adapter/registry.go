@@ -0,0 +1,65 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"sort"
+	"sync"
+
+	"istio.io/istio/mixer/pkg/adapter/metrics"
+)
+
+// Registry is the default, in-memory MetricsRegistry. The mixer constructs
+// one at startup and lets each Builder register its own Descriptions from
+// Validate, so adapters can enumerate the full set of known metrics without
+// duplicating adapter config. It is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	byName map[string]metrics.Description
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]metrics.Description)}
+}
+
+// Register adds d to the registry, replacing any existing Description with
+// the same name.
+func (r *Registry) Register(d metrics.Description) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byName[d.Name] = d
+}
+
+// All implements MetricsRegistry.
+func (r *Registry) All() []metrics.Description {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]metrics.Description, 0, len(r.byName))
+	for _, d := range r.byName {
+		all = append(all, d)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// Lookup implements MetricsRegistry.
+func (r *Registry) Lookup(name string) (metrics.Description, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.byName[name]
+	return d, ok
+}
@@ -0,0 +1,71 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"testing"
+
+	"istio.io/istio/mixer/pkg/adapter/metrics"
+)
+
+func TestRegistryLookup(t *testing.T) {
+	r := NewRegistry()
+
+	if _, ok := r.Lookup("requests_total"); ok {
+		t.Fatal("Lookup on an empty registry should return false")
+	}
+
+	r.Register(metrics.Description{Name: "requests_total", Kind: int(Counter)})
+
+	d, ok := r.Lookup("requests_total")
+	if !ok {
+		t.Fatal("Lookup should find a registered Description")
+	}
+	if d.Name != "requests_total" {
+		t.Errorf("Name = %q, want %q", d.Name, "requests_total")
+	}
+}
+
+func TestRegistryRegisterReplacesExisting(t *testing.T) {
+	r := NewRegistry()
+
+	r.Register(metrics.Description{Name: "requests_total", Unit: "1"})
+	r.Register(metrics.Description{Name: "requests_total", Unit: "By"})
+
+	d, ok := r.Lookup("requests_total")
+	if !ok {
+		t.Fatal("expected the metric to still be registered")
+	}
+	if d.Unit != "By" {
+		t.Errorf("Unit = %q, want the later registration's %q", d.Unit, "By")
+	}
+}
+
+func TestRegistryAllIsSortedByName(t *testing.T) {
+	r := NewRegistry()
+	r.Register(metrics.Description{Name: "z_metric"})
+	r.Register(metrics.Description{Name: "a_metric"})
+	r.Register(metrics.Description{Name: "m_metric"})
+
+	all := r.All()
+	if len(all) != 3 {
+		t.Fatalf("len(All()) = %d, want 3", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Name > all[i].Name {
+			t.Errorf("All() not sorted by name: %q before %q", all[i-1].Name, all[i].Name)
+		}
+	}
+}
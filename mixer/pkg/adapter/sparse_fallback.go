@@ -0,0 +1,68 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import "math"
+
+// ToExplicitDistribution converts a sparse exponential histogram into a
+// DistributionValue against explicitBounds, for backends whose
+// MetricsBuilder.AcceptsSparseDistributions() is false. explicitBounds is
+// typically the builder's own DefaultExplicitBounds(), mirroring the OTel
+// SDK's WithExplicitBucketBoundaries view option.
+//
+// The conversion is necessarily lossy: a sparse bucket's count is assigned
+// to the explicit bucket containing that sparse bucket's edge nearest zero
+// (base^i for a positive bucket, -base^i for a negative one), so
+// observations within a wide sparse bucket that straddles an explicit
+// boundary are not split proportionally.
+func ToExplicitDistribution(sparse *SparseDistributionValue, explicitBounds []float64) (*DistributionValue, error) {
+	counts := make([]uint64, len(explicitBounds)+1)
+	var sampleCount uint64
+	var sum float64
+
+	base := math.Exp2(math.Exp2(-float64(sparse.Scale)))
+
+	assign := func(idx int32, count uint64, negative bool) {
+		lowerEdge := math.Pow(base, float64(idx))
+		if negative {
+			lowerEdge = -lowerEdge
+		}
+		counts[explicitBucketForValue(lowerEdge, explicitBounds)] += count
+		sampleCount += count
+		sum += lowerEdge * float64(count)
+	}
+
+	for idx, count := range sparse.PositiveBuckets {
+		assign(idx, count, false)
+	}
+	for idx, count := range sparse.NegativeBuckets {
+		assign(idx, count, true)
+	}
+	if sparse.ZeroCount > 0 {
+		counts[explicitBucketForValue(0, explicitBounds)] += sparse.ZeroCount
+		sampleCount += sparse.ZeroCount
+	}
+
+	return &DistributionValue{Counts: counts, Sum: sum, SampleCount: sampleCount}, nil
+}
+
+func explicitBucketForValue(value float64, bounds []float64) int {
+	for i, bound := range bounds {
+		if value < bound {
+			return i
+		}
+	}
+	return len(bounds)
+}
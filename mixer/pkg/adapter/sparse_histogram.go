@@ -0,0 +1,121 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"math"
+	"sync"
+)
+
+// SparseHistogram accumulates observations for a single series recorded
+// against a NativeExponentialBuckets definition. It starts at the
+// definition's configured Scale and, on exceeding MaxBuckets, halves
+// resolution by decrementing Scale and merging adjacent buckets pairwise,
+// which is a constant-time reindex (see mergeDown).
+type SparseHistogram struct {
+	def NativeExponentialBuckets
+
+	mu        sync.Mutex
+	scale     int32
+	zeroCount uint64
+	positive  map[int32]uint64
+	negative  map[int32]uint64
+}
+
+// NewSparseHistogram returns an empty SparseHistogram for def.
+func NewSparseHistogram(def NativeExponentialBuckets) *SparseHistogram {
+	return &SparseHistogram{
+		def:      def,
+		scale:    def.Scale,
+		positive: make(map[int32]uint64),
+		negative: make(map[int32]uint64),
+	}
+}
+
+// Observe records a single raw observation.
+func (h *SparseHistogram) Observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	abs := math.Abs(value)
+	if abs < h.def.ZeroThreshold {
+		h.zeroCount++
+		return
+	}
+
+	buckets := h.positive
+	if value < 0 {
+		buckets = h.negative
+	}
+	buckets[bucketIndex(abs, h.scale)]++
+
+	for int32(len(h.positive)+len(h.negative)) > h.def.MaxBuckets {
+		h.mergeDown()
+	}
+}
+
+// Value returns a snapshot of the histogram's current state as a
+// SparseDistributionValue suitable for attaching to a Value.
+func (h *SparseHistogram) Value() *SparseDistributionValue {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	positive := make(map[int32]uint64, len(h.positive))
+	for k, v := range h.positive {
+		positive[k] = v
+	}
+	negative := make(map[int32]uint64, len(h.negative))
+	for k, v := range h.negative {
+		negative[k] = v
+	}
+	return &SparseDistributionValue{
+		Scale:           h.scale,
+		ZeroCount:       h.zeroCount,
+		PositiveBuckets: positive,
+		NegativeBuckets: negative,
+	}
+}
+
+// bucketIndex returns the index i such that base^i <= value < base^(i+1),
+// where base = 2^(2^-scale).
+func bucketIndex(value float64, scale int32) int32 {
+	return int32(math.Floor(math.Log2(value) * math.Exp2(float64(scale))))
+}
+
+// mergeDown halves resolution by decrementing scale and merging each pair
+// of adjacent buckets from the old scale into a single bucket at the new
+// scale. Because halving scale doubles bucket width, the new index is
+// simply the old index divided by two (rounding toward negative infinity),
+// a constant-time shift rather than a recomputation from raw values.
+func (h *SparseHistogram) mergeDown() {
+	h.scale--
+	h.positive = mergeBuckets(h.positive)
+	h.negative = mergeBuckets(h.negative)
+}
+
+func mergeBuckets(buckets map[int32]uint64) map[int32]uint64 {
+	merged := make(map[int32]uint64, len(buckets))
+	for idx, count := range buckets {
+		merged[floorDiv2(idx)] += count
+	}
+	return merged
+}
+
+func floorDiv2(i int32) int32 {
+	if i >= 0 {
+		return i / 2
+	}
+	return -((-i + 1) / 2)
+}
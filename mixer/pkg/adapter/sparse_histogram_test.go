@@ -0,0 +1,95 @@
+// Copyright 2017 The Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import "testing"
+
+func TestSparseHistogramZeroBucket(t *testing.T) {
+	h := NewSparseHistogram(NativeExponentialBuckets{Scale: 2, MaxBuckets: 100, ZeroThreshold: 1e-3})
+
+	h.Observe(0)
+	h.Observe(1e-4)
+	h.Observe(-1e-4)
+
+	v := h.Value()
+	if v.ZeroCount != 3 {
+		t.Errorf("ZeroCount = %d, want 3", v.ZeroCount)
+	}
+	if len(v.PositiveBuckets) != 0 || len(v.NegativeBuckets) != 0 {
+		t.Errorf("expected no positive/negative buckets, got %v / %v", v.PositiveBuckets, v.NegativeBuckets)
+	}
+}
+
+func TestSparseHistogramPositiveAndNegativeBuckets(t *testing.T) {
+	h := NewSparseHistogram(NativeExponentialBuckets{Scale: 0, MaxBuckets: 100, ZeroThreshold: 0})
+
+	h.Observe(4)
+	h.Observe(4)
+	h.Observe(-4)
+
+	v := h.Value()
+	idx := bucketIndex(4, 0)
+	if v.PositiveBuckets[idx] != 2 {
+		t.Errorf("PositiveBuckets[%d] = %d, want 2", idx, v.PositiveBuckets[idx])
+	}
+	if v.NegativeBuckets[idx] != 1 {
+		t.Errorf("NegativeBuckets[%d] = %d, want 1", idx, v.NegativeBuckets[idx])
+	}
+}
+
+func TestSparseHistogramMergeDownOnMaxBuckets(t *testing.T) {
+	h := NewSparseHistogram(NativeExponentialBuckets{Scale: 4, MaxBuckets: 2, ZeroThreshold: 0})
+
+	// Each observation lands in a distinct bucket at scale 4; once a third
+	// distinct bucket is added the histogram must halve resolution to stay
+	// within MaxBuckets, merging adjacent buckets pairwise.
+	h.Observe(1)
+	h.Observe(2)
+	h.Observe(4)
+
+	v := h.Value()
+	if int32(len(v.PositiveBuckets)) > h.def.MaxBuckets {
+		t.Errorf("len(PositiveBuckets) = %d, want <= %d after merge", len(v.PositiveBuckets), h.def.MaxBuckets)
+	}
+	if v.Scale >= 4 {
+		t.Errorf("Scale = %d, want < 4 after merge", v.Scale)
+	}
+
+	var total uint64
+	for _, c := range v.PositiveBuckets {
+		total += c
+	}
+	if total != 3 {
+		t.Errorf("total observations after merge = %d, want 3", total)
+	}
+}
+
+func TestFloorDiv2(t *testing.T) {
+	cases := []struct {
+		in, want int32
+	}{
+		{4, 2},
+		{5, 2},
+		{0, 0},
+		{-1, -1},
+		{-2, -1},
+		{-3, -2},
+	}
+	for _, c := range cases {
+		if got := floorDiv2(c.in); got != c.want {
+			t.Errorf("floorDiv2(%d) = %d, want %d", c.in, got, c.want)
+		}
+	}
+}